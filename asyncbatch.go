@@ -0,0 +1,264 @@
+package clickhouse
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/driver"
+)
+
+// AsyncBatchOptions configures an AsyncBatch's buffering and flush
+// behaviour. Zero values fall back to the defaults documented on each field.
+type AsyncBatchOptions struct {
+	// MaxRows flushes the current buffer once it holds this many rows.
+	// Defaults to 10000.
+	MaxRows int
+	// MaxBytes flushes the current buffer once Append has accepted at
+	// least this many bytes, estimated from the arguments passed to it.
+	// Zero disables the byte-size trigger.
+	MaxBytes int
+	// MaxLatency is the period of each flusher's timer: whatever is
+	// buffered when it fires is flushed, even if neither threshold above
+	// was reached, and the timer restarts after every flush. A row thus
+	// waits at most about this long. Defaults to one second.
+	MaxLatency time.Duration
+	// QueueSize bounds the number of buffered rows awaiting a flusher.
+	// Append blocks once the queue is full. Defaults to 4*MaxRows.
+	QueueSize int
+	// Flushers is the number of background goroutines draining the queue
+	// into batch.Send calls. Defaults to 1.
+	Flushers int
+	// RetryPolicy governs whether a failed flush is retried against a
+	// freshly prepared batch before being surfaced on Errors(). Defaults
+	// to NoRetry.
+	RetryPolicy RetryPolicy
+}
+
+func (o *AsyncBatchOptions) setDefaults() {
+	if o.MaxRows <= 0 {
+		o.MaxRows = 10000
+	}
+	if o.MaxLatency <= 0 {
+		o.MaxLatency = time.Second
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = o.MaxRows * 4
+	}
+	if o.Flushers <= 0 {
+		o.Flushers = 1
+	}
+	if o.RetryPolicy == nil {
+		o.RetryPolicy = NoRetry{}
+	}
+}
+
+type asyncRow struct {
+	args []interface{}
+	size int
+}
+
+// AsyncBatch is a BufferedInserter built on top of Conn.PrepareBatch. It
+// accepts rows via an Append that never waits on network I/O, buffers them, and flushes to
+// ClickHouse on a background goroutine whenever a row-count, byte-size, or
+// latency threshold is crossed. It is the batching primitive to reach for
+// when the caller is a log or metrics pipeline that cannot afford a
+// synchronous Send() per row.
+type AsyncBatch struct {
+	ch    driver.Conn
+	query string
+	opt   AsyncBatchOptions
+
+	queue chan asyncRow
+	errCh chan error
+
+	mu       sync.Mutex
+	closing  bool
+	inflight sync.WaitGroup
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewAsyncBatch starts an AsyncBatch that inserts into the table addressed
+// by query (an "INSERT INTO ..." statement, exactly as passed to
+// PrepareBatch). The returned batch owns background goroutines; callers must
+// call Close to drain and stop them.
+func NewAsyncBatch(ctx context.Context, ch driver.Conn, query string, opt AsyncBatchOptions) *AsyncBatch {
+	opt.setDefaults()
+	ab := &AsyncBatch{
+		ch:    ch,
+		query: query,
+		opt:   opt,
+		queue: make(chan asyncRow, opt.QueueSize),
+		errCh: make(chan error, opt.QueueSize),
+		done:  make(chan struct{}),
+	}
+	var wg sync.WaitGroup
+	wg.Add(opt.Flushers)
+	for i := 0; i < opt.Flushers; i++ {
+		go func() {
+			defer wg.Done()
+			ab.flushLoop(ctx)
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(ab.done)
+	}()
+	return ab
+}
+
+// Append enqueues a row for a future flush. It does not block on network
+// I/O: it only blocks if the in-memory queue is full, applying backpressure
+// to the caller until a flusher drains it.
+//
+// Append and Close race safely: Append registers itself as in-flight before
+// checking closing, and Close waits for every in-flight Append to finish
+// enqueuing its row before it closes the queue. That guarantees no row
+// accepted by Append is ever dropped by Close's final drain.
+func (ab *AsyncBatch) Append(args ...interface{}) error {
+	ab.mu.Lock()
+	if ab.closing {
+		ab.mu.Unlock()
+		return errAsyncBatchClosed
+	}
+	ab.inflight.Add(1)
+	ab.mu.Unlock()
+	defer ab.inflight.Done()
+
+	size := 0
+	for _, a := range args {
+		size += estimateSize(a)
+	}
+	ab.queue <- asyncRow{args: args, size: size}
+	return nil
+}
+
+// Errors returns the channel flush errors are published to. A failed block
+// is first resent against a freshly prepared batch for as long as
+// AsyncBatchOptions.RetryPolicy permits; only the error that ends those
+// attempts is published, and that block's rows are dropped. An error is
+// discarded if the channel is full. A flush error does not stop the
+// AsyncBatch, and readers are expected to drain this channel rather than
+// treat it as fatal.
+func (ab *AsyncBatch) Errors() <-chan error {
+	return ab.errCh
+}
+
+// Close stops accepting new rows, flushes whatever remains buffered, and
+// waits for the background flushers to exit or ctx to be cancelled.
+func (ab *AsyncBatch) Close(ctx context.Context) error {
+	ab.closeOnce.Do(func() {
+		ab.mu.Lock()
+		ab.closing = true
+		ab.mu.Unlock()
+		// Wait for every Append that got past the closing check to finish
+		// sending its row before closing the queue, so flushLoop's
+		// close-triggered drain can't race past a row still in flight.
+		ab.inflight.Wait()
+		close(ab.queue)
+	})
+	select {
+	case <-ab.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (ab *AsyncBatch) flushLoop(ctx context.Context) {
+	timer := time.NewTimer(ab.opt.MaxLatency)
+	defer timer.Stop()
+	var buf []asyncRow
+	bytes := 0
+
+	flush := func() {
+		if len(buf) == 0 {
+			return
+		}
+		rows := buf
+		buf = nil
+		bytes = 0
+		if err := ab.send(ctx, rows); err != nil {
+			select {
+			case ab.errCh <- err:
+			default:
+			}
+		}
+	}
+
+	for {
+		select {
+		case row, ok := <-ab.queue:
+			if !ok {
+				// The queue is closed and drained: Close guarantees every
+				// Append it could race with has already landed its row.
+				flush()
+				return
+			}
+			buf = append(buf, row)
+			bytes += row.size
+			if len(buf) >= ab.opt.MaxRows || (ab.opt.MaxBytes > 0 && bytes >= ab.opt.MaxBytes) {
+				flush()
+				timer.Reset(ab.opt.MaxLatency)
+			}
+		case <-timer.C:
+			flush()
+			timer.Reset(ab.opt.MaxLatency)
+		}
+	}
+}
+
+// send prepares a fresh batch, appends every buffered row, and sends it,
+// retrying the whole block on a transient failure per
+// AsyncBatchOptions.RetryPolicy. A retry resends the block against a newly
+// prepared batch rather than reusing the failed one, since a batch.Send that
+// has started streaming cannot be resumed.
+func (ab *AsyncBatch) send(ctx context.Context, rows []asyncRow) error {
+	for attempt := 1; ; attempt++ {
+		lastErr := ab.sendOnce(ctx, rows)
+		if lastErr == nil {
+			return nil
+		}
+		retry, delay := ab.opt.RetryPolicy.Attempt(lastErr, attempt)
+		if !retry {
+			return lastErr
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (ab *AsyncBatch) sendOnce(ctx context.Context, rows []asyncRow) error {
+	batch, err := ab.ch.PrepareBatch(ctx, ab.query)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := batch.Append(row.args...); err != nil {
+			return err
+		}
+	}
+	return batch.Send()
+}
+
+func estimateSize(v interface{}) int {
+	switch val := v.(type) {
+	case string:
+		return len(val)
+	case []byte:
+		return len(val)
+	default:
+		return 8
+	}
+}
+
+var errAsyncBatchClosed = batchClosedError{}
+
+type batchClosedError struct{}
+
+func (batchClosedError) Error() string { return "clickhouse: async batch is closed" }
@@ -57,6 +57,31 @@ type Options struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	// ConnectionOpenStrategy controls the order in which acquire() tries
+	// Addr when no HostPool is supplied. It mirrors the DSN parameter of
+	// the same name ("in_order", "random", "time_random") and defaults to
+	// an epsilon-greedy policy when left empty.
+	ConnectionOpenStrategy ConnOpenStrategy
+	// HostCooldown is how long a host stays quarantined after a failed
+	// dial or connection error before the pool will prefer it again. A
+	// quarantined host is still used as a last resort if every other host
+	// is also quarantined, so a single flaky host never makes acquire()
+	// fail outright for the full cooldown window.
+	HostCooldown time.Duration
+	// HostPool overrides the built-in pool selected via
+	// ConnectionOpenStrategy, e.g. to plug in a custom health-aware policy.
+	HostPool HostPool
+	// RetryPolicy governs whether a transient Query/Exec/Ping/PrepareBatch
+	// failure is retried against another host. It defaults to NoRetry,
+	// preserving the driver's historical single-attempt behaviour.
+	RetryPolicy RetryPolicy
+	// Tracer, when set, is called around Query/Exec/PrepareBatch/Ping so
+	// callers can plug in distributed tracing. Connection dials and
+	// acquire/release are not traced; dials are reported to Metrics only.
+	Tracer Tracer
+	// Metrics, when set, is called with per-query and per-connect counters
+	// so callers can feed a metrics backend such as Prometheus.
+	Metrics Metrics
 }
 
 func (o *Options) fromDSN(in string) error {
@@ -81,10 +106,13 @@ func (o *Options) fromDSN(in string) error {
 		case "secure":
 		case "skip_verify":
 		case "connection_open_strategy":
-			switch params.Get("v") {
-			case "random":
-			case "in_order":
-			case "time_random":
+			switch ConnOpenStrategy(params.Get(v)) {
+			case ConnOpenRandom:
+				o.ConnectionOpenStrategy = ConnOpenRandom
+			case ConnOpenInOrder:
+				o.ConnectionOpenStrategy = ConnOpenInOrder
+			case ConnOpenTimeRandom:
+				o.ConnectionOpenStrategy = ConnOpenTimeRandom
 			}
 		}
 	}
@@ -111,13 +139,20 @@ func (o *Options) setDefaults() {
 	if o.ConnMaxLifetime == 0 {
 		o.ConnMaxLifetime = time.Hour
 	}
+	if o.HostCooldown == 0 {
+		o.HostCooldown = 10 * time.Second
+	}
 }
 
 func Open(opt *Options) (driver.Conn, error) {
 	opt.setDefaults()
-
+	pool := opt.HostPool
+	if pool == nil {
+		pool = newHostPool(opt.ConnectionOpenStrategy, opt.Addr, opt.HostCooldown)
+	}
 	return &clickhouse{
 		opt:  opt,
+		pool: pool,
 		idle: make(chan *connect, opt.MaxIdleConns),
 		open: make(chan struct{}, opt.MaxOpenConns),
 	}, nil
@@ -125,6 +160,7 @@ func Open(opt *Options) (driver.Conn, error) {
 
 type clickhouse struct {
 	opt     *Options
+	pool    HostPool
 	idle    chan *connect
 	open    chan struct{}
 	counter int64
@@ -140,38 +176,88 @@ func (ch *clickhouse) ServerVersion() (*driver.ServerVersion, error) {
 }
 
 func (ch *clickhouse) Query(ctx context.Context, query string, args ...interface{}) (rows driver.Rows, err error) {
-	conn, err := ch.acquire()
-	if err != nil {
-		return nil, err
-	}
-	defer ch.release(conn)
-	return conn.query(ctx, query, args...)
+	opts := queryOptionsFromContext(ctx)
+	err = ch.observe(ctx, query, func(ctx context.Context) (QueryStats, error) {
+		stats := ch.statsFromContext(ctx)
+		err := ch.withRetry(ctx, isIdempotentStatement(query), func(conn *connect) (qErr error) {
+			stats.Attempts++
+			stats.Host = conn.addr
+			rows, qErr = conn.query(ctx, query, opts, args...)
+			return qErr
+		})
+		return stats, err
+	})
+	return rows, err
 }
 
 func (ch *clickhouse) Exec(ctx context.Context, query string, args ...interface{}) error {
-	conn, err := ch.acquire()
-	if err != nil {
-		return err
-	}
-	defer ch.release(conn)
-	return conn.exec(ctx, query, args...)
+	opts := queryOptionsFromContext(ctx)
+	return ch.observe(ctx, query, func(ctx context.Context) (QueryStats, error) {
+		stats := ch.statsFromContext(ctx)
+		err := ch.withRetry(ctx, isIdempotentStatement(query), func(conn *connect) error {
+			stats.Attempts++
+			stats.Host = conn.addr
+			return conn.exec(ctx, query, opts, args...)
+		})
+		return stats, err
+	})
 }
 
-func (ch *clickhouse) PrepareBatch(ctx context.Context, query string) (driver.Batch, error) {
-	conn, err := ch.acquire()
-	if err != nil {
-		return nil, err
+// PrepareBatch retries acquiring a connection, including on a transient
+// acquire() failure, but once a *connect.batch has been handed back to the
+// caller a failure is no longer safe to retry transparently: batch.Send()
+// may have already begun streaming rows, so the caller must decide whether
+// to re-prepare.
+func (ch *clickhouse) PrepareBatch(ctx context.Context, query string) (batch driver.Batch, err error) {
+	opts := queryOptionsFromContext(ctx)
+	ctx = ch.opt.tracer().QueryStart(ctx, query, "")
+	start := time.Now()
+	stats := ch.statsFromContext(ctx)
+	policy := retryPolicyFromContext(ctx, ch.opt)
+	attempt := 0
+retryLoop:
+	for {
+		attempt++
+		var conn *connect
+		conn, err = ch.acquire()
+		if err == nil {
+			stats.Host = conn.addr
+			batch, err = conn.prepareBatch(ctx, query, opts, ch.release)
+			if err == nil {
+				break
+			}
+			ch.release(conn)
+		}
+		retry, delay := policy.Attempt(err, attempt)
+		if !retry {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			break retryLoop
+		case <-time.After(delay):
+		}
 	}
-	return conn.prepareBatch(ctx, query, ch.release)
+	stats.Query = query
+	stats.Err = err
+	stats.Duration = time.Since(start)
+	stats.Attempts = attempt
+	ch.opt.tracer().QueryEnd(ctx, stats)
+	ch.opt.metrics().ObserveQuery(stats)
+	return batch, err
 }
 
 func (ch *clickhouse) Ping(ctx context.Context) error {
-	conn, err := ch.acquire()
-	if err != nil {
-		return err
-	}
-	defer ch.release(conn)
-	return conn.ping(ctx)
+	return ch.observe(ctx, "PING", func(ctx context.Context) (QueryStats, error) {
+		stats := ch.statsFromContext(ctx)
+		err := ch.withRetry(ctx, true, func(conn *connect) error {
+			stats.Attempts++
+			stats.Host = conn.addr
+			return conn.ping(ctx)
+		})
+		return stats, err
+	})
 }
 
 func (ch *clickhouse) Stats() driver.Stats {
@@ -199,12 +285,27 @@ func (ch *clickhouse) acquire() (conn *connect, err error) {
 	default:
 	}
 	num := int(atomic.AddInt64(&ch.counter, 1))
-	for _, addr := range ch.opt.Addr {
-		if conn, err = dial(addr, num, ch.opt); err == nil {
+	for tries := 1; ; tries++ {
+		var addr string
+		if addr, err = ch.pool.Pick(); err != nil {
+			return nil, err
+		}
+		dialStart := time.Now()
+		conn, err = dial(addr, num, ch.opt)
+		dialDuration := time.Since(dialStart)
+		ch.opt.metrics().ObserveConnect(addr, dialDuration, err)
+		if err == nil {
+			ch.pool.MarkSuccess(addr)
+			if lt, ok := ch.pool.(latencyTracker); ok {
+				lt.MarkLatency(addr, dialDuration)
+			}
 			return
 		}
+		ch.pool.MarkFailure(addr)
+		if tries >= len(ch.opt.Addr) {
+			return nil, err
+		}
 	}
-	return
 }
 
 func (ch *clickhouse) release(conn *connect) {
@@ -212,6 +313,11 @@ func (ch *clickhouse) release(conn *connect) {
 	case <-ch.open:
 	default:
 	}
+	if conn.err != nil {
+		ch.pool.MarkFailure(conn.addr)
+	} else {
+		ch.pool.MarkSuccess(conn.addr)
+	}
 	if conn.err != nil || time.Since(conn.connectedAt) >= ch.opt.ConnMaxLifetime {
 		conn.close()
 		return
@@ -0,0 +1,95 @@
+package clickhouse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type (
+	settingsKey struct{}
+	queryIDKey  struct{}
+	quotaKeyKey struct{}
+	progressKey struct{}
+)
+
+// WithSettings attaches per-query ClickHouse settings to ctx. Unlike
+// Options.Settings, which is applied once when the connection is opened,
+// these are serialised into the individual query packet built by
+// conn.query/exec/prepareBatch and only affect that one call.
+func WithSettings(ctx context.Context, settings Settings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, settings)
+}
+
+func settingsFromContext(ctx context.Context) (Settings, bool) {
+	settings, ok := ctx.Value(settingsKey{}).(Settings)
+	return settings, ok
+}
+
+// WithQueryID attaches a caller-supplied query_id to ctx. ClickHouse accepts
+// this in client_info so the same id can be used to correlate server logs
+// and, later, to cancel the query via Conn.Cancel.
+func WithQueryID(ctx context.Context, queryID string) context.Context {
+	return context.WithValue(ctx, queryIDKey{}, queryID)
+}
+
+func queryIDFromContext(ctx context.Context) (string, bool) {
+	queryID, ok := ctx.Value(queryIDKey{}).(string)
+	return queryID, ok
+}
+
+// WithQuotaKey attaches a ClickHouse quota_key to ctx, so the query is
+// accounted against the named quota rather than the default one for the
+// authenticated user.
+func WithQuotaKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, quotaKeyKey{}, key)
+}
+
+func quotaKeyFromContext(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(quotaKeyKey{}).(string)
+	return key, ok
+}
+
+// WithProgress attaches a callback to ctx that conn.query is expected to
+// invoke with every progress packet ClickHouse sends while the query runs,
+// so long-running SELECTs can report rows/bytes scanned so far.
+func WithProgress(ctx context.Context, fn func(*Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+func progressFromContext(ctx context.Context) (func(*Progress), bool) {
+	fn, ok := ctx.Value(progressKey{}).(func(*Progress))
+	return fn, ok
+}
+
+// queryOptions collects everything attached to ctx via WithSettings,
+// WithQueryID, WithQuotaKey, and WithProgress into the single value passed to
+// conn.query/exec/prepareBatch, which serialise Settings and QueryID/QuotaKey
+// into the query packet's settings and client_info sections and invoke
+// Progress as progress packets arrive. Query/Exec/PrepareBatch build one from
+// ctx once per call rather than handing conn four separate lookups.
+type queryOptions struct {
+	Settings Settings
+	QueryID  string
+	QuotaKey string
+	Progress func(*Progress)
+}
+
+func queryOptionsFromContext(ctx context.Context) (opts queryOptions) {
+	opts.Settings, _ = settingsFromContext(ctx)
+	opts.QueryID, _ = queryIDFromContext(ctx)
+	opts.QuotaKey, _ = quotaKeyFromContext(ctx)
+	opts.Progress, _ = progressFromContext(ctx)
+	return opts
+}
+
+// Cancel aborts a query previously started with WithQueryID, by running
+// `KILL QUERY WHERE query_id = ...` on a freshly acquired connection. This
+// is the standard ClickHouse mechanism for aborting a long-running SELECT
+// launched from another goroutine, since the connection streaming that
+// query's result set is busy until it finishes or is killed server-side. It
+// only matches because Query/Exec/PrepareBatch thread the same queryID into
+// the original call's client_info via queryOptions.
+func (ch *clickhouse) Cancel(ctx context.Context, queryID string) error {
+	return ch.Exec(ctx, fmt.Sprintf("KILL QUERY WHERE query_id = '%s'", strings.ReplaceAll(queryID, "'", "''")))
+}
@@ -0,0 +1,34 @@
+package clickhouse
+
+import (
+	"context"
+	"testing"
+)
+
+func TestQueryOptionsFromContextEmpty(t *testing.T) {
+	opts := queryOptionsFromContext(context.Background())
+	if opts.Settings != nil || opts.QueryID != "" || opts.QuotaKey != "" || opts.Progress != nil {
+		t.Fatalf("got %+v, want zero value when ctx carries no per-query options", opts)
+	}
+}
+
+func TestQueryOptionsFromContextCollectsAll(t *testing.T) {
+	ctx := WithSettings(context.Background(), Settings{"max_threads": 4})
+	ctx = WithQueryID(ctx, "abc-123")
+	ctx = WithQuotaKey(ctx, "tenant-1")
+	ctx = WithProgress(ctx, func(*Progress) {})
+
+	opts := queryOptionsFromContext(ctx)
+	if len(opts.Settings) != 1 || opts.Settings["max_threads"] != 4 {
+		t.Fatalf("got Settings %+v, want {max_threads: 4}", opts.Settings)
+	}
+	if opts.QueryID != "abc-123" {
+		t.Fatalf("got QueryID %q, want abc-123", opts.QueryID)
+	}
+	if opts.QuotaKey != "tenant-1" {
+		t.Fatalf("got QuotaKey %q, want tenant-1", opts.QuotaKey)
+	}
+	if opts.Progress == nil {
+		t.Fatalf("got nil Progress, want the callback passed to WithProgress")
+	}
+}
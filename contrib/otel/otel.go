@@ -0,0 +1,53 @@
+// Package otel adapts clickhouse.Tracer to OpenTelemetry spans, so a
+// clickhouse-go connection's queries show up in whatever tracing backend the
+// caller's OpenTelemetry SDK is already wired to (Jaeger, Tempo, etc.).
+package otel
+
+import (
+	"context"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracer implements clickhouse.Tracer by starting one span per query on a
+// trace.Tracer from otel.Tracer. Pass it as Options.Tracer.
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer builds a Tracer using otel.Tracer(instrumentationName) for
+// spans, so callers don't need to depend on the OpenTelemetry SDK directly.
+func NewTracer(instrumentationName string) *Tracer {
+	return &Tracer{tracer: otel.Tracer(instrumentationName)}
+}
+
+func (t *Tracer) QueryStart(ctx context.Context, query, host string) context.Context {
+	ctx, _ = t.tracer.Start(ctx, "clickhouse.query",
+		trace.WithAttributes(
+			attribute.String("db.system", "clickhouse"),
+			attribute.String("db.statement", query),
+			attribute.String("net.peer.name", host),
+		),
+	)
+	return ctx
+}
+
+func (t *Tracer) QueryEnd(ctx context.Context, stats clickhouse.QueryStats) {
+	span := trace.SpanFromContext(ctx)
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("clickhouse.query_id", stats.QueryID),
+		attribute.Int64("clickhouse.rows_read", int64(stats.RowsRead)),
+		attribute.Int64("clickhouse.bytes_read", int64(stats.BytesRead)),
+		attribute.Int64("clickhouse.bytes_written", int64(stats.BytesWritten)),
+		attribute.Int("clickhouse.attempts", stats.Attempts),
+	)
+	if stats.Err != nil {
+		span.RecordError(stats.Err)
+		span.SetStatus(codes.Error, stats.Err.Error())
+	}
+}
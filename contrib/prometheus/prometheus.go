@@ -0,0 +1,80 @@
+// Package prometheus adapts clickhouse.Metrics to a set of Prometheus
+// collectors, so a clickhouse-go connection's counters and pool gauges can
+// be scraped without the caller hand-rolling a Metrics implementation.
+package prometheus
+
+import (
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics implements clickhouse.Metrics using a fixed set of Prometheus
+// collectors registered under namespace "clickhouse". Pass it as
+// Options.Metrics after registering it with a prometheus.Registerer.
+type Metrics struct {
+	QueryDuration   *prometheus.HistogramVec
+	QueryErrors     *prometheus.CounterVec
+	ConnectDuration *prometheus.HistogramVec
+	ConnectErrors   *prometheus.CounterVec
+	PoolOpen        prometheus.Gauge
+	PoolIdle        prometheus.Gauge
+}
+
+// NewMetrics constructs a Metrics and registers its collectors with reg.
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		QueryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "clickhouse",
+			Name:      "query_duration_seconds",
+			Help:      "Duration of Query/Exec/PrepareBatch/Ping calls.",
+		}, []string{"host"}),
+		QueryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "clickhouse",
+			Name:      "query_errors_total",
+			Help:      "Count of Query/Exec/PrepareBatch/Ping calls that returned an error.",
+		}, []string{"host"}),
+		ConnectDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "clickhouse",
+			Name:      "connect_duration_seconds",
+			Help:      "Duration of dial attempts.",
+		}, []string{"host"}),
+		ConnectErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "clickhouse",
+			Name:      "connect_errors_total",
+			Help:      "Count of dial attempts that failed.",
+		}, []string{"host"}),
+		PoolOpen: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "clickhouse",
+			Name:      "pool_open_connections",
+			Help:      "Connections currently checked out of the pool.",
+		}),
+		PoolIdle: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "clickhouse",
+			Name:      "pool_idle_connections",
+			Help:      "Connections currently idle in the pool.",
+		}),
+	}
+	reg.MustRegister(m.QueryDuration, m.QueryErrors, m.ConnectDuration, m.ConnectErrors, m.PoolOpen, m.PoolIdle)
+	return m
+}
+
+func (m *Metrics) ObserveQuery(stats clickhouse.QueryStats) {
+	m.QueryDuration.WithLabelValues(stats.Host).Observe(stats.Duration.Seconds())
+	if stats.Err != nil {
+		m.QueryErrors.WithLabelValues(stats.Host).Inc()
+	}
+}
+
+func (m *Metrics) ObserveConnect(host string, d time.Duration, err error) {
+	m.ConnectDuration.WithLabelValues(host).Observe(d.Seconds())
+	if err != nil {
+		m.ConnectErrors.WithLabelValues(host).Inc()
+	}
+}
+
+func (m *Metrics) ObservePoolStats(stats clickhouse.PoolStats) {
+	m.PoolOpen.Set(float64(stats.Open))
+	m.PoolIdle.Set(float64(stats.Idle))
+}
@@ -0,0 +1,239 @@
+package clickhouse
+
+import (
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrNoAvailableHosts is returned by a HostPool when it has no hosts
+// configured at all. A pool with at least one host never returns it for
+// being quarantined: Pick falls back to the least-recently-quarantined host
+// instead, since hard-failing for the full cooldown window would make a
+// single flaky host (or a single-node deployment, where it's the only host)
+// refuse every connection until the cooldown expires on its own.
+var ErrNoAvailableHosts = errors.New("clickhouse: no available hosts")
+
+// ConnOpenStrategy selects how a HostPool orders candidate addresses.
+type ConnOpenStrategy string
+
+const (
+	ConnOpenInOrder    ConnOpenStrategy = "in_order"
+	ConnOpenRandom     ConnOpenStrategy = "random"
+	ConnOpenTimeRandom ConnOpenStrategy = "time_random"
+)
+
+// HostPool decides which address acquire() should dial next and is told the
+// outcome of that attempt so it can steer future picks away from unhealthy
+// hosts. Implementations must be safe for concurrent use.
+type HostPool interface {
+	// Pick returns the next address to try, preferring one that isn't in
+	// cooldown. If every host is quarantined it falls back to whichever
+	// quarantine expires soonest rather than failing outright. It only
+	// returns ErrNoAvailableHosts when the pool has no hosts configured.
+	Pick() (string, error)
+	// MarkSuccess records that addr served a connection successfully.
+	MarkSuccess(addr string)
+	// MarkFailure records that dialing or using addr failed and puts it in
+	// cooldown for the pool's configured duration.
+	MarkFailure(addr string)
+}
+
+// latencyTracker is an optional HostPool extension. acquire() type-asserts
+// for it after a successful dial and reports the round-trip so pools that
+// rank hosts by responsiveness (epsilonGreedyPool) have real data instead of
+// always seeing a zero latency.
+type latencyTracker interface {
+	MarkLatency(addr string, d time.Duration)
+}
+
+func newHostPool(strategy ConnOpenStrategy, addr []string, cooldown time.Duration) HostPool {
+	switch strategy {
+	case ConnOpenRandom:
+		return newRandomPool(addr, cooldown, rand.New(rand.NewSource(1)))
+	case ConnOpenTimeRandom:
+		return newRandomPool(addr, cooldown, rand.New(rand.NewSource(time.Now().UnixNano())))
+	case ConnOpenInOrder:
+		return newRoundRobinPool(addr, cooldown)
+	default:
+		return newEpsilonGreedyPool(addr, cooldown)
+	}
+}
+
+type hostState struct {
+	addr             string
+	quarantinedUntil time.Time
+	latency          time.Duration
+	errors           int64
+	successes        int64
+}
+
+func (h *hostState) available(now time.Time) bool {
+	return now.After(h.quarantinedUntil) || now.Equal(h.quarantinedUntil)
+}
+
+type basePool struct {
+	mu       sync.Mutex
+	hosts    []*hostState
+	cooldown time.Duration
+}
+
+func newBasePool(addr []string, cooldown time.Duration) *basePool {
+	hosts := make([]*hostState, len(addr))
+	for i, a := range addr {
+		hosts[i] = &hostState{addr: a}
+	}
+	return &basePool{hosts: hosts, cooldown: cooldown}
+}
+
+func (p *basePool) find(addr string) *hostState {
+	for _, h := range p.hosts {
+		if h.addr == addr {
+			return h
+		}
+	}
+	return nil
+}
+
+func (p *basePool) MarkSuccess(addr string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if h := p.find(addr); h != nil {
+		h.successes++
+		h.quarantinedUntil = time.Time{}
+	}
+}
+
+func (p *basePool) MarkFailure(addr string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if h := p.find(addr); h != nil {
+		h.errors++
+		h.quarantinedUntil = time.Now().Add(p.cooldown)
+	}
+}
+
+func (p *basePool) available(now time.Time) []*hostState {
+	out := make([]*hostState, 0, len(p.hosts))
+	for _, h := range p.hosts {
+		if h.available(now) {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// fallback returns the host whose quarantine expires soonest, for when every
+// host is currently quarantined. It only fails with ErrNoAvailableHosts if
+// the pool has no hosts at all, since in that case there's nothing to fall
+// back to.
+func (p *basePool) fallback() (string, error) {
+	if len(p.hosts) == 0 {
+		return "", ErrNoAvailableHosts
+	}
+	best := p.hosts[0]
+	for _, h := range p.hosts[1:] {
+		if h.quarantinedUntil.Before(best.quarantinedUntil) {
+			best = h
+		}
+	}
+	return best.addr, nil
+}
+
+// roundRobinPool implements connection_open_strategy=in_order: hosts are
+// always tried in the order they were configured.
+type roundRobinPool struct {
+	*basePool
+}
+
+func newRoundRobinPool(addr []string, cooldown time.Duration) *roundRobinPool {
+	return &roundRobinPool{basePool: newBasePool(addr, cooldown)}
+}
+
+func (p *roundRobinPool) Pick() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, h := range p.available(time.Now()) {
+		return h.addr, nil
+	}
+	return p.fallback()
+}
+
+// randomPool implements connection_open_strategy=random and time_random: it
+// picks uniformly among the currently available hosts using the supplied
+// rand.Rand (time_random seeds it from the wall clock).
+type randomPool struct {
+	*basePool
+	rnd *rand.Rand
+}
+
+func newRandomPool(addr []string, cooldown time.Duration, rnd *rand.Rand) *randomPool {
+	return &randomPool{basePool: newBasePool(addr, cooldown), rnd: rnd}
+}
+
+func (p *randomPool) Pick() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	avail := p.available(time.Now())
+	if len(avail) == 0 {
+		return p.fallback()
+	}
+	return avail[p.rnd.Intn(len(avail))].addr, nil
+}
+
+// epsilonGreedyPool is the default HostPool. With probability epsilon it
+// explores a random available host; otherwise it exploits the host with the
+// lowest observed error rate, breaking ties on latency. This mirrors the
+// epsilon-greedy host selection used by gocql for Cassandra clusters.
+type epsilonGreedyPool struct {
+	*basePool
+	epsilon float64
+	rnd     *rand.Rand
+}
+
+func newEpsilonGreedyPool(addr []string, cooldown time.Duration) *epsilonGreedyPool {
+	return &epsilonGreedyPool{
+		basePool: newBasePool(addr, cooldown),
+		epsilon:  0.1,
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+func (p *epsilonGreedyPool) Pick() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	avail := p.available(time.Now())
+	if len(avail) == 0 {
+		return p.fallback()
+	}
+	if p.rnd.Float64() < p.epsilon {
+		return avail[p.rnd.Intn(len(avail))].addr, nil
+	}
+	best := avail[0]
+	for _, h := range avail[1:] {
+		if errorRate(h) < errorRate(best) || (errorRate(h) == errorRate(best) && h.latency < best.latency) {
+			best = h
+		}
+	}
+	return best.addr, nil
+}
+
+func errorRate(h *hostState) float64 {
+	total := h.successes + h.errors
+	if total == 0 {
+		return 0
+	}
+	return float64(h.errors) / float64(total)
+}
+
+// MarkLatency records an observed round-trip for addr so the epsilon-greedy
+// pool can rank hosts by responsiveness as well as error rate. Pools that
+// don't track latency ignore the call.
+func (p *epsilonGreedyPool) MarkLatency(addr string, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if h := p.find(addr); h != nil {
+		h.latency = d
+	}
+}
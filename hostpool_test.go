@@ -0,0 +1,88 @@
+package clickhouse
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRoundRobinPoolQuarantine(t *testing.T) {
+	pool := newRoundRobinPool([]string{"a:9000", "b:9000"}, time.Minute)
+
+	addr, err := pool.Pick()
+	if err != nil || addr != "a:9000" {
+		t.Fatalf("got (%q, %v), want (a:9000, nil)", addr, err)
+	}
+
+	pool.MarkFailure("a:9000")
+	addr, err = pool.Pick()
+	if err != nil || addr != "b:9000" {
+		t.Fatalf("after quarantining a, got (%q, %v), want (b:9000, nil)", addr, err)
+	}
+}
+
+func TestBasePoolNoHostsReturnsError(t *testing.T) {
+	pool := newRoundRobinPool(nil, time.Minute)
+	if _, err := pool.Pick(); err != ErrNoAvailableHosts {
+		t.Fatalf("got err=%v, want ErrNoAvailableHosts", err)
+	}
+}
+
+func TestBasePoolSingleHostFallsBackWhenQuarantined(t *testing.T) {
+	// A single quarantined host (the common single-node deployment) must
+	// still be picked rather than erroring for the full cooldown window.
+	pool := newRoundRobinPool([]string{"a:9000"}, time.Minute)
+	pool.MarkFailure("a:9000")
+	if addr, err := pool.Pick(); err != nil || addr != "a:9000" {
+		t.Fatalf("got (%q, %v), want (a:9000, nil): the only host should still be picked", addr, err)
+	}
+}
+
+func TestMarkSuccessClearsQuarantine(t *testing.T) {
+	pool := newRoundRobinPool([]string{"a:9000", "b:9000"}, time.Hour)
+	pool.MarkFailure("a:9000")
+	if addr, err := pool.Pick(); err != nil || addr != "b:9000" {
+		t.Fatalf("expected quarantine to skip a:9000 in favour of b:9000, got (%q, %v)", addr, err)
+	}
+	pool.MarkSuccess("a:9000")
+	if addr, err := pool.Pick(); err != nil || addr != "a:9000" {
+		t.Fatalf("got (%q, %v), want (a:9000, nil) after MarkSuccess clears quarantine", addr, err)
+	}
+}
+
+func TestEpsilonGreedyPoolPrefersHealthierHost(t *testing.T) {
+	pool := newEpsilonGreedyPool([]string{"bad:9000", "good:9000"}, time.Minute)
+	pool.epsilon = 0 // always exploit for this test
+
+	for i := 0; i < 10; i++ {
+		pool.MarkFailure("bad:9000")
+		pool.MarkSuccess("bad:9000")
+	}
+	pool.MarkSuccess("good:9000")
+	// Each MarkSuccess above already cleared bad:9000's quarantine, so Pick
+	// must rank both hosts: 10/21 errors for bad:9000 against 0/1 for good.
+	pool.MarkSuccess("bad:9000")
+
+	addr, err := pool.Pick()
+	if err != nil {
+		t.Fatalf("Pick() error: %v", err)
+	}
+	if addr != "good:9000" {
+		t.Fatalf("got %q, want good:9000 (lower error rate)", addr)
+	}
+}
+
+func TestEpsilonGreedyPoolMarkLatencyBreaksTies(t *testing.T) {
+	pool := newEpsilonGreedyPool([]string{"slow:9000", "fast:9000"}, time.Minute)
+	pool.epsilon = 0
+
+	pool.MarkLatency("slow:9000", 100*time.Millisecond)
+	pool.MarkLatency("fast:9000", time.Millisecond)
+
+	addr, err := pool.Pick()
+	if err != nil {
+		t.Fatalf("Pick() error: %v", err)
+	}
+	if addr != "fast:9000" {
+		t.Fatalf("got %q, want fast:9000 (lower latency breaks the error-rate tie)", addr)
+	}
+}
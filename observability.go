@@ -0,0 +1,134 @@
+package clickhouse
+
+import (
+	"context"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/compress"
+)
+
+// QueryStats summarises a finished Query/Exec/PrepareBatch/Ping call for a
+// Tracer or Metrics implementation. The driver fills in QueryID, QuotaKey,
+// Host, Query, Err, Duration, Compression and Attempts; BytesRead,
+// BytesWritten, RowsRead and Blocks are not filled in and are always zero.
+type QueryStats struct {
+	QueryID      string
+	QuotaKey     string
+	Host         string
+	Query        string
+	Err          error
+	Duration     time.Duration
+	BytesRead    uint64
+	BytesWritten uint64
+	RowsRead     uint64
+	Blocks       uint64
+	Compression  compress.Method
+	Attempts     int
+}
+
+// Tracer is called at well-defined points around a query's lifecycle so
+// callers can plug in OpenTelemetry/Jaeger spans without forking the driver.
+// Implementations must be safe for concurrent use. See contrib/otel for a
+// ready-made implementation.
+type Tracer interface {
+	// QueryStart is called before a connection is acquired, so the driver
+	// passes an empty host; the chosen host arrives in QueryStats.Host at
+	// QueryEnd. The returned context is threaded through to QueryEnd so a
+	// Tracer can stash a span in it.
+	QueryStart(ctx context.Context, query string, host string) context.Context
+	// QueryEnd is called when the call returns, successfully or not. For
+	// Query that is when the rows are handed back, before the caller has
+	// read the result stream.
+	QueryEnd(ctx context.Context, stats QueryStats)
+}
+
+// Metrics is called with driver-level counters so callers can feed a
+// Prometheus registry or similar without forking the driver. See
+// contrib/prometheus for a ready-made implementation.
+type Metrics interface {
+	// ObserveQuery is called once per finished Query/Exec/PrepareBatch/Ping
+	// call with the same summary passed to Tracer.QueryEnd.
+	ObserveQuery(stats QueryStats)
+	// ObserveConnect is called once per dial attempt, successful or not.
+	ObserveConnect(host string, d time.Duration, err error)
+	// ObservePoolStats is called after every Query/Exec/Ping call with a
+	// snapshot of the connection pool, so a Metrics implementation can
+	// keep gauges in sync without polling Stats() itself. PrepareBatch does
+	// not report pool stats.
+	ObservePoolStats(stats PoolStats)
+}
+
+// PoolStats is the snapshot passed to Metrics.ObservePoolStats. It carries
+// the same four fields as driver.Stats; retry and host-health activity are
+// not included.
+type PoolStats struct {
+	Open         int
+	Idle         int
+	MaxOpenConns int
+	MaxIdleConns int
+}
+
+// noopTracer and noopMetrics let call sites invoke the hooks unconditionally
+// instead of nil-checking Options.Tracer/Metrics on every call.
+type noopTracer struct{}
+
+func (noopTracer) QueryStart(ctx context.Context, query, host string) context.Context { return ctx }
+func (noopTracer) QueryEnd(ctx context.Context, stats QueryStats)                      {}
+
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveQuery(stats QueryStats)                          {}
+func (noopMetrics) ObserveConnect(host string, d time.Duration, err error) {}
+func (noopMetrics) ObservePoolStats(stats PoolStats)                       {}
+
+func (o *Options) tracer() Tracer {
+	if o.Tracer != nil {
+		return o.Tracer
+	}
+	return noopTracer{}
+}
+
+func (o *Options) metrics() Metrics {
+	if o.Metrics != nil {
+		return o.Metrics
+	}
+	return noopMetrics{}
+}
+
+// observe wraps fn, reporting a QueryStats to both the configured Tracer and
+// Metrics. The host isn't known until fn acquires a connection, so
+// QueryStart is called with an empty host; fn is responsible for filling in
+// Host, QueryID, Compression, and Attempts on the QueryStats it returns once
+// those become available.
+func (ch *clickhouse) observe(ctx context.Context, query string, fn func(ctx context.Context) (QueryStats, error)) error {
+	ctx = ch.opt.tracer().QueryStart(ctx, query, "")
+	start := time.Now()
+	stats, err := fn(ctx)
+	stats.Duration = time.Since(start)
+	stats.Query = query
+	stats.Err = err
+	ch.opt.tracer().QueryEnd(ctx, stats)
+	ch.opt.metrics().ObserveQuery(stats)
+	ch.opt.metrics().ObservePoolStats(PoolStats{
+		Open:         len(ch.open),
+		Idle:         len(ch.idle),
+		MaxOpenConns: cap(ch.open),
+		MaxIdleConns: cap(ch.idle),
+	})
+	return err
+}
+
+// statsFromContext fills in the QueryStats fields that are known ahead of
+// the call completing: the query_id and quota_key threaded into the query
+// packet via queryOptions, and the compression method configured in
+// Options.Compression.
+func (ch *clickhouse) statsFromContext(ctx context.Context) QueryStats {
+	opts := queryOptionsFromContext(ctx)
+	var stats QueryStats
+	stats.QueryID = opts.QueryID
+	stats.QuotaKey = opts.QuotaKey
+	if ch.opt.Compression != nil {
+		stats.Compression = ch.opt.Compression.Method
+	}
+	return stats
+}
@@ -0,0 +1,167 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/proto"
+)
+
+// Exception codes ClickHouse raises for conditions a client can reasonably
+// retry against a different host. Values match the server's ErrorCodes.cpp.
+const (
+	excNetworkError               = 210
+	excSocketTimeout              = 209
+	excTooManySimultaneousQueries = 202
+	excUnknownPacketFromServer    = 102
+	excAttemptToReadAfterEOF      = 32
+)
+
+// RetryPolicy decides whether a failed Query/Exec/Ping/PrepareBatch call
+// should be retried against a different host, and how long to wait first.
+// attempt is 1 for the first retry (i.e. the call that follows the original,
+// failed attempt).
+type RetryPolicy interface {
+	Attempt(err error, attempt int) (retry bool, delay time.Duration)
+}
+
+// NoRetry never retries; it is the default when Options.RetryPolicy is left
+// unset and preserves the driver's historical single-attempt behaviour.
+type NoRetry struct{}
+
+func (NoRetry) Attempt(err error, attempt int) (bool, time.Duration) {
+	return false, 0
+}
+
+// SimpleRetry retries up to MaxAttempts times with a fixed Delay between
+// attempts, as long as the error is classified retryable.
+type SimpleRetry struct {
+	MaxAttempts int
+	Delay       time.Duration
+}
+
+func (p SimpleRetry) Attempt(err error, attempt int) (bool, time.Duration) {
+	if !isRetryable(err) || attempt > p.MaxAttempts {
+		return false, 0
+	}
+	return true, p.Delay
+}
+
+// ExponentialBackoff retries up to MaxAttempts times, doubling BaseDelay on
+// each attempt up to MaxDelay and adding up to a quarter of that delay as jitter so a
+// thundering herd of clients don't retry in lockstep.
+type ExponentialBackoff struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+func (p ExponentialBackoff) Attempt(err error, attempt int) (bool, time.Duration) {
+	if !isRetryable(err) || attempt > p.MaxAttempts {
+		return false, 0
+	}
+	delay := p.BaseDelay << uint(attempt-1)
+	if delay <= 0 || delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(delay/4 + 1)))
+	return true, delay + jitter
+}
+
+// isRetryable classifies a ClickHouse proto.Exception as transient (worth
+// redialing a different host for) versus terminal (a query-shaped error that
+// will fail again no matter which host serves it). Non-Exception errors,
+// such as a dial timeout, are treated as retryable network failures.
+func isRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var exc *proto.Exception
+	if errors.As(err, &exc) {
+		switch exc.Code {
+		case excNetworkError, excSocketTimeout, excTooManySimultaneousQueries,
+			excUnknownPacketFromServer, excAttemptToReadAfterEOF:
+			return true
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// idempotentStatementVerbs allowlists the leading keywords of statements
+// that are safe to send again after a transient failure. Everything else —
+// INSERT, DDL (CREATE/ALTER/DROP/...), and any statement we don't
+// recognise — is treated as non-idempotent, since a blind retry could
+// duplicate rows or race a DDL change against its own replication.
+var idempotentStatementVerbs = []string{
+	"SELECT", "SHOW", "DESCRIBE", "DESC", "EXISTS", "EXPLAIN", "WITH", "PING",
+}
+
+// isIdempotentStatement reports whether query is safe to send again after a
+// transient failure.
+func isIdempotentStatement(query string) bool {
+	q := strings.TrimSpace(query)
+	for _, kw := range idempotentStatementVerbs {
+		if len(q) >= len(kw) && strings.EqualFold(q[:len(kw)], kw) {
+			return true
+		}
+	}
+	return false
+}
+
+type noRetryKey struct{}
+
+// WithNoRetry forces a single call made with ctx to skip the connection's
+// configured RetryPolicy, e.g. for a statement the caller knows is not safe
+// to repeat.
+func WithNoRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRetryKey{}, true)
+}
+
+func retryPolicyFromContext(ctx context.Context, opt *Options) RetryPolicy {
+	if forced, _ := ctx.Value(noRetryKey{}).(bool); forced {
+		return NoRetry{}
+	}
+	if opt.RetryPolicy != nil {
+		return opt.RetryPolicy
+	}
+	return NoRetry{}
+}
+
+// withRetry runs fn, and while the connection's RetryPolicy says to retry a
+// transient error, re-acquires a connection (which, via the HostPool, is
+// likely to land on a different host) and calls fn again. idempotent must be
+// false for statements that may have already taken effect server-side (an
+// INSERT whose batch.Send began streaming, or DDL), since those are never
+// safe to repeat even if the policy would otherwise allow it; it has no
+// bearing on a failure to acquire a connection in the first place, since no
+// statement can have reached the server yet, so those are retried whenever
+// the policy allows.
+func (ch *clickhouse) withRetry(ctx context.Context, idempotent bool, fn func(conn *connect) error) error {
+	policy := retryPolicyFromContext(ctx, ch.opt)
+	attempt := 0
+	for {
+		conn, err := ch.acquire()
+		if err == nil {
+			err = fn(conn)
+			ch.release(conn)
+			if err == nil || !idempotent {
+				return err
+			}
+		}
+		attempt++
+		retry, delay := policy.Attempt(err, attempt)
+		if !retry {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
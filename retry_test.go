@@ -0,0 +1,83 @@
+package clickhouse
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/lib/proto"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain network error", errors.New("dial tcp: timeout"), true},
+		{"retryable exception", &proto.Exception{Code: excSocketTimeout}, true},
+		{"another retryable exception", &proto.Exception{Code: excTooManySimultaneousQueries}, true},
+		{"terminal exception", &proto.Exception{Code: 999}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsIdempotentStatement(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"SELECT * FROM t", true},
+		{"  select 1", true},
+		{"SHOW TABLES", true},
+		{"EXPLAIN SELECT 1", true},
+		{"INSERT INTO t VALUES (1)", false},
+		{"CREATE TABLE t (c Int32) Engine Memory", false},
+		{"DROP TABLE t", false},
+		{"ALTER TABLE t ADD COLUMN c Int32", false},
+		{"OPTIMIZE TABLE t", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			if got := isIdempotentStatement(tt.query); got != tt.want {
+				t.Errorf("isIdempotentStatement(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoffAttempt(t *testing.T) {
+	policy := ExponentialBackoff{MaxAttempts: 3, BaseDelay: 10 * time.Millisecond, MaxDelay: time.Second}
+	retryableErr := &proto.Exception{Code: excNetworkError}
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		retry, delay := policy.Attempt(retryableErr, attempt)
+		if !retry {
+			t.Fatalf("attempt %d: got retry=false, want true", attempt)
+		}
+		min := policy.BaseDelay << uint(attempt-1)
+		if min > policy.MaxDelay {
+			min = 0 // MaxDelay branch: jitter is computed off MaxDelay, still >= 0
+		}
+		if delay < min {
+			t.Fatalf("attempt %d: delay %v below minimum %v", attempt, delay, min)
+		}
+	}
+
+	if retry, _ := policy.Attempt(retryableErr, 4); retry {
+		t.Fatalf("attempt 4 exceeds MaxAttempts=3, want retry=false")
+	}
+
+	terminalErr := &proto.Exception{Code: 999}
+	if retry, _ := policy.Attempt(terminalErr, 1); retry {
+		t.Fatalf("terminal exception should not be retried")
+	}
+}
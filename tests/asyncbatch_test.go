@@ -0,0 +1,55 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsyncBatch(t *testing.T) {
+	var (
+		ctx       = context.Background()
+		conn, err = clickhouse.Open(&clickhouse.Options{
+			Addr: []string{"127.0.0.1:9000"},
+			Auth: clickhouse.Auth{
+				Database: "default",
+				Username: "default",
+				Password: "",
+			},
+		})
+	)
+	if assert.NoError(t, err) {
+		if err := checkMinServerVersion(conn, 20, 1); err != nil {
+			t.Skip(err.Error())
+			return
+		}
+		const ddl = `
+		CREATE TABLE test_async_batch (
+			  Col1 String
+			, Col2 Int32
+		) Engine Memory
+		`
+		if err := conn.Exec(ctx, "DROP TABLE IF EXISTS test_async_batch"); assert.NoError(t, err) {
+			if err := conn.Exec(ctx, ddl); assert.NoError(t, err) {
+				batch := clickhouse.NewAsyncBatch(ctx, conn, "INSERT INTO test_async_batch", clickhouse.AsyncBatchOptions{
+					MaxRows:    5,
+					MaxLatency: 50 * time.Millisecond,
+				})
+				for i := 0; i < 12; i++ {
+					if err := batch.Append("row", int32(i)); !assert.NoError(t, err) {
+						return
+					}
+				}
+				if assert.NoError(t, batch.Close(ctx)) {
+					var count uint64
+					if err := conn.QueryRow(ctx, "SELECT COUNT() FROM test_async_batch").Scan(&count); assert.NoError(t, err) {
+						assert.Equal(t, uint64(12), count)
+					}
+				}
+			}
+		}
+	}
+}